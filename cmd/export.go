@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/logicmonitor/k8s-release-manager/pkg/action"
 	"github.com/logicmonitor/k8s-release-manager/pkg/config"
 	"github.com/logicmonitor/k8s-release-manager/pkg/export"
 	"github.com/logicmonitor/k8s-release-manager/pkg/state"
@@ -58,6 +61,12 @@ writing state to the same backend path, causing conflicts, overwrites, chaos.`,
 			PollingInterval: viper.GetInt64("pollingInterval"),
 			Namespaces: 		 ns,
 		}
+
+		// Update and WriteRelease/DeleteRelease take the same backend
+		// storage lock transfer/import do, so honor the same --lock-ttl
+		// and --lock-wait here.
+		rlsmgrconfig.Backend.LockTTL = lockTTL
+		rlsmgrconfig.Backend.LockWait = lockWait
 	},
 }
 
@@ -66,11 +75,15 @@ func init() { // nolint: dupl
 	exportCmd.PersistentFlags().IntVarP(&pollingInterval, "polling-interval", "p", 30, "Specify, in seconds, how frequently the daemon should export the current state")
 	exportCmd.PersistentFlags().StringVarP(&releaseName, "release-name", "", "", "Specify the Release Manager daemon's Helm release name")
 	exportCmd.PersistentFlags().StringVarP(&namespaces, "namespaces", "", "", "A comma-delimited list of namespaces to export. The default behavior is to export all namespaces")
+	exportCmd.PersistentFlags().DurationVarP(&lockTTL, "lock-ttl", "", 30*time.Second, "How long a state update holds the backend storage lock before it expires")
+	exportCmd.PersistentFlags().DurationVarP(&lockWait, "lock-wait", "", 60*time.Second, "How long to wait for the backend storage lock before failing")
 	err := bindConfigFlags(exportCmd, map[string]string{
 		"daemon":          "daemon",
 		"pollingInterval": "polling-interval",
 		"releaseName":     "release-name",
 		"namespaces":			 "namespaces",
+		"lockTTL":         "lock-ttl",
+		"lockWait":        "lock-wait",
 	})
 	if err != nil {
 		fmt.Println(err)
@@ -81,12 +94,13 @@ func init() { // nolint: dupl
 
 func exportRun(cmd *cobra.Command, args []string) { // nolint: dupl
 	// Instantiate the Release Manager.
-	export, err := export.New(rlsmgrconfig, mgrstate)
+	e, err := export.New(rlsmgrconfig, mgrstate, logrusLogger)
 	if err != nil {
 		log.Fatalf("Failed to create Release Manager exporter: %v", err)
 	}
 
-	err = export.Run()
+	a := &action.ActionExport{Export: e}
+	err = a.Run(context.Background())
 	if err != nil {
 		log.Errorf("%v", err)
 	}
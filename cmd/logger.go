@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	rlog "github.com/logicmonitor/k8s-release-manager/pkg/log"
+	log "github.com/sirupsen/logrus"
+)
+
+// logrusLogger adapts the CLI's configured logrus instance to the generic
+// rlog.Logger signature expected by library packages, so that the logging
+// backend and level choices live here rather than inside those packages.
+// It forwards level to the matching logrus method so operators still see
+// Warn/Error messages by default, instead of every message collapsing to
+// Debug and being hidden at logrus's default level.
+func logrusLogger(level rlog.Level, format string, args ...interface{}) {
+	switch level {
+	case rlog.Error:
+		log.Errorf(format, args...)
+	case rlog.Warn:
+		log.Warnf(format, args...)
+	case rlog.Info:
+		log.Infof(format, args...)
+	default:
+		log.Debugf(format, args...)
+	}
+}
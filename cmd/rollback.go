@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/logicmonitor/k8s-release-manager/pkg/action"
+	"github.com/logicmonitor/k8s-release-manager/pkg/config"
+	"github.com/logicmonitor/k8s-release-manager/pkg/rollback"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var rollbackReleaseName string
+var rollbackNamespace string
+var rollbackExcludeNamespaces string
+var revision int32
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Roll back a Helm release to a previously stored revision",
+	Long: `Release Manager Rollback will retrieve a previously stored revision of a
+release from the configured backend and roll the currently installed release
+back to it via helm rollback.
+
+By default, Rollback targets the revision immediately prior to the most
+recently stored one. Use --revision to target a specific stored revision.
+
+As with Import, rolling back a release is designed to fail safely if the
+requested revision can't be found. Use --force to proceed in the face of
+unexpected state, and --dry-run to preview the rollback without mutating
+the cluster.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		valid := validateCommonConfig()
+		if !valid {
+			failAuth(cmd)
+		}
+
+		rlsmgrconfig.Rollback = &config.RollbackConfig{
+			ReleaseName:       rollbackReleaseName,
+			Namespace:         rollbackNamespace,
+			ExcludeNamespaces: parseExcludeNamespaces(rollbackExcludeNamespaces),
+			Revision:          revision,
+			Force:             force,
+		}
+	},
+}
+
+func init() { // nolint: dupl
+	rollbackCmd.PersistentFlags().StringVarP(&rollbackReleaseName, "release-name", "", "", "The name of the release to roll back")
+	rollbackCmd.PersistentFlags().StringVarP(&rollbackNamespace, "namespace", "", "", "Only consider stored revisions in the specified namespace")
+	rollbackCmd.PersistentFlags().StringVarP(&rollbackExcludeNamespaces, "exclude-namespaces", "", "", "A comma-delimited list of namespaces to skip. Ignored if --namespace is set")
+	rollbackCmd.PersistentFlags().Int32VarP(&revision, "revision", "", 0, "The stored revision to roll back to. Defaults to the revision prior to the most recently stored one")
+	rollbackCmd.PersistentFlags().BoolVarP(&force, "force", "", false, "Skip safety checks")
+	err := bindConfigFlags(rollbackCmd, map[string]string{
+		"rollbackReleaseName":       "release-name",
+		"rollbackNamespace":         "namespace",
+		"rollbackExcludeNamespaces": "exclude-namespaces",
+		"revision":                  "revision",
+		"force":                     "force",
+	})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	RootCmd.AddCommand(rollbackCmd)
+}
+
+func rollbackRun(cmd *cobra.Command, args []string) { // nolint: dupl
+	rb, err := rollback.New(rlsmgrconfig, mgrstate, logrusLogger)
+	if err != nil {
+		log.Fatalf("Failed to create Release Manager rollback: %v", err)
+	}
+
+	a := &action.ActionRollback{Rollback: rb}
+	err = a.Run(context.Background())
+	if err != nil {
+		log.Errorf("%v", err)
+	}
+}
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/logicmonitor/k8s-release-manager/pkg/action"
 	"github.com/logicmonitor/k8s-release-manager/pkg/config"
 	"github.com/logicmonitor/k8s-release-manager/pkg/transfer"
 	log "github.com/sirupsen/logrus"
@@ -13,6 +17,14 @@ import (
 var force bool
 var newStoragePath string
 var releaseTimeoutSec int
+var lockTTL time.Duration
+var lockWait time.Duration
+var installer string
+var rudderAddress string
+var onExists string
+var diffOnly bool
+var importNamespace string
+var importExcludeNamespaces string
 
 var importCmd = &cobra.Command{
 	Use:   "import",
@@ -27,9 +39,12 @@ in the remote state, and --new-path is not set, this command will fail. If
 you're really sure that this is an operation you want to perform (it probably
 isn't), you can set --force to ignore safety checks.
 
-Import is designed to fail if a release already exists with the same name as
-a stored release. This is by design. If you to overwrite an existing release,
-you should delete it first using helm delete --purge.`,
+By default, Import skips a release that already exists with the same name as
+a stored release, so repeated imports are safe to run. Set --on-exists=upgrade
+to push the stored release's chart and values onto the existing release
+instead, or --on-exists=replace to delete and reinstall it. --diff-only never
+touches the cluster; it prints a unified diff between what's deployed and
+what's stored, so you can preview an import before choosing --on-exists.`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		valid := validateCommonConfig()
 		if !valid {
@@ -38,9 +53,21 @@ you should delete it first using helm delete --purge.`,
 
 		rlsmgrconfig.Helm.ReleaseTimeoutSec = int64(releaseTimeoutSec)
 		rlsmgrconfig.Transfer = &config.TransferConfig{
-			Force:          force,
-			NewStoragePath: newStoragePath,
+			Force:             force,
+			NewStoragePath:    newStoragePath,
+			Installer:         installer,
+			RudderAddress:     rudderAddress,
+			OnExists:          onExists,
+			DiffOnly:          diffOnly,
+			Namespace:         importNamespace,
+			ExcludeNamespaces: parseExcludeNamespaces(importExcludeNamespaces),
 		}
+
+		// --lock-ttl and --lock-wait live on Backend, not Transfer: State
+		// and Transfer both acquire the same backend storage lock, and a
+		// single config field means they can't drift out of sync.
+		rlsmgrconfig.Backend.LockTTL = lockTTL
+		rlsmgrconfig.Backend.LockWait = lockWait
 	},
 }
 
@@ -48,10 +75,26 @@ func init() { // nolint: dupl
 	importCmd.PersistentFlags().BoolVarP(&force, "force", "", false, "Skip safety checks")
 	importCmd.PersistentFlags().IntVarP(&releaseTimeoutSec, "release-timeout", "", 300, "The time, in seconds, to wait for an individual Helm release to install")
 	importCmd.PersistentFlags().StringVarP(&newStoragePath, "new-path", "", "", "When installing an exported Release Manager release, update the value of --path")
+	importCmd.PersistentFlags().DurationVarP(&lockTTL, "lock-ttl", "", 30*time.Second, "How long this import holds the backend storage lock before it expires")
+	importCmd.PersistentFlags().DurationVarP(&lockWait, "lock-wait", "", 60*time.Second, "How long to wait for the backend storage lock before failing")
+	importCmd.PersistentFlags().StringVarP(&installer, "installer", "", "helm", "How to deploy stored releases: helm, exec, or rudder (rudder is recognized but not yet implemented)")
+	importCmd.PersistentFlags().StringVarP(&rudderAddress, "rudder-address", "", "", "Address of the Rudder gRPC service, used when --installer=rudder")
+	importCmd.PersistentFlags().StringVarP(&onExists, "on-exists", "", "skip", "What to do when a stored release already exists in the cluster: skip, upgrade, or replace")
+	importCmd.PersistentFlags().BoolVarP(&diffOnly, "diff-only", "", false, "Print a diff of stored vs. deployed releases and exit without changing the cluster")
+	importCmd.PersistentFlags().StringVarP(&importNamespace, "namespace", "", "", "Only import stored releases in the specified namespace")
+	importCmd.PersistentFlags().StringVarP(&importExcludeNamespaces, "exclude-namespaces", "", "", "A comma-delimited list of namespaces to skip. Ignored if --namespace is set")
 	err := bindConfigFlags(importCmd, map[string]string{
-		"force":          "force",
-		"releaseTimeout": "polling-timeout",
-		"newPath":        "new-path",
+		"force":             "force",
+		"releaseTimeout":    "polling-timeout",
+		"newPath":           "new-path",
+		"lockTTL":           "lock-ttl",
+		"lockWait":          "lock-wait",
+		"installer":         "installer",
+		"rudderAddress":     "rudder-address",
+		"onExists":          "on-exists",
+		"diffOnly":          "diff-only",
+		"namespace":         "namespace",
+		"excludeNamespaces": "exclude-namespaces",
 	})
 	if err != nil {
 		fmt.Println(err)
@@ -61,13 +104,24 @@ func init() { // nolint: dupl
 }
 
 func importRun(cmd *cobra.Command, args []string) { // nolint: dupl
-	transfer, err := transfer.New(rlsmgrconfig, mgrstate)
+	t, err := transfer.New(rlsmgrconfig, mgrstate, logrusLogger)
 	if err != nil {
 		log.Fatalf("Failed to create Release Manager transfer: %v", err)
 	}
 
-	err = transfer.Run()
+	a := &action.ActionTransfer{Transfer: t}
+	err = a.Run(context.Background())
 	if err != nil {
 		log.Errorf("%v", err)
 	}
 }
+
+// parseExcludeNamespaces splits a comma-delimited --exclude-namespaces
+// value into its namespace names. An empty string yields no namespaces.
+func parseExcludeNamespaces(namespaces string) []string {
+	if namespaces == "" {
+		return nil
+	}
+	ns := strings.Split(strings.Replace(namespaces, " ", "", -1), ",")
+	return ns
+}
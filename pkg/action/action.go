@@ -0,0 +1,65 @@
+// Package action unifies the flows the release manager drives (import and
+// transfer are both driven by pkg/transfer.Transfer; export, rollback, and
+// uninstall each have their own package) behind a single Action interface,
+// so that both the CLI and embedding controllers can drive them the same
+// way. The import, export, and rollback CLI commands all go through an
+// Action; ActionUninstall has no CLI command wired to it yet, since
+// pkg/uninstall has no corresponding cobra command in this tree.
+package action
+
+import (
+	"context"
+
+	"github.com/logicmonitor/k8s-release-manager/pkg/export"
+	"github.com/logicmonitor/k8s-release-manager/pkg/rollback"
+	"github.com/logicmonitor/k8s-release-manager/pkg/transfer"
+	"github.com/logicmonitor/k8s-release-manager/pkg/uninstall"
+)
+
+// Action is a unit of work the release manager can run
+type Action interface {
+	Run(ctx context.Context) error
+}
+
+// ActionExport runs a release export
+type ActionExport struct {
+	Export *export.Export
+}
+
+// Run executes the export
+func (a *ActionExport) Run(ctx context.Context) error {
+	return a.Export.Run()
+}
+
+// ActionTransfer runs a stored-release transfer. There's no ActionImport:
+// the import command wraps a *transfer.Transfer in an ActionTransfer too
+// (see cmd/transfer.go's importRun), since import and transfer are the same
+// flow with different flags, not two implementations.
+type ActionTransfer struct {
+	Transfer *transfer.Transfer
+}
+
+// Run executes the transfer
+func (a *ActionTransfer) Run(ctx context.Context) error {
+	return a.Transfer.Run()
+}
+
+// ActionRollback rolls a release back to a previously stored revision
+type ActionRollback struct {
+	Rollback *rollback.Rollback
+}
+
+// Run executes the rollback
+func (a *ActionRollback) Run(ctx context.Context) error {
+	return a.Rollback.Run()
+}
+
+// ActionUninstall removes a release from the cluster
+type ActionUninstall struct {
+	Uninstall *uninstall.Uninstall
+}
+
+// Run executes the uninstall
+func (a *ActionUninstall) Run(ctx context.Context) error {
+	return a.Uninstall.Run()
+}
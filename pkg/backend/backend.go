@@ -0,0 +1,41 @@
+// Package backend abstracts where release manager state and releases are
+// persisted. S3Backend and FilesystemBackend are the two drivers currently
+// implemented; both satisfy the same Backend interface so pkg/state doesn't
+// need to know which one it's talking to.
+package backend
+
+import "time"
+
+// Backend persists and retrieves release manager state and releases at a
+// configured path, and arbitrates exclusive access to that path across
+// concurrent release managers via AcquireLock/ReleaseLock.
+type Backend interface {
+	// List returns the names of every entry directly under path (not
+	// recursively), analogous to a directory listing.
+	List(path string) ([]string, error)
+	// Read returns the contents stored at path.
+	Read(path string) ([]byte, error)
+	// Write stores data at path, creating or overwriting it.
+	Write(path string, data []byte) error
+	// Delete removes path. It is not an error for path to not exist.
+	Delete(path string) error
+	// PathSeparator returns the separator used to join path segments for
+	// this backend.
+	PathSeparator() string
+
+	// AcquireLock grants the caller exclusive access to path for ttl,
+	// returning a token that must be presented to ReleaseLock or RenewLock.
+	// It returns an error if path is currently locked by someone else and
+	// that lock hasn't expired.
+	AcquireLock(path string, ttl time.Duration) (token string, err error)
+	// RenewLock extends the lock at path previously granted to token to
+	// expire ttl from now. It returns an error, without extending anything,
+	// if token no longer holds the lock (e.g. it expired and someone else
+	// acquired it first) — callers must treat that as having lost the lock,
+	// not as a transient failure to retry.
+	RenewLock(path, token string, ttl time.Duration) error
+	// ReleaseLock releases the lock at path previously granted to token. It
+	// is a no-op, not an error, if the lock already expired or was already
+	// released.
+	ReleaseLock(path, token string) error
+}
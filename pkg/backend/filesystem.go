@@ -0,0 +1,164 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// lockSuffix is appended to path to name its lockfile, e.g.
+// "releases/myapp.json.lock".
+const lockSuffix = ".lock"
+
+// FilesystemBackend stores release manager state and releases as files on
+// local disk, for operators who mount a shared volume instead of using an
+// object store.
+type FilesystemBackend struct{}
+
+// List returns the names of the entries directly under path.
+func (f *FilesystemBackend) List(path string) ([]string, error) {
+	entries, err := ioutil.ReadDir(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names, nil
+}
+
+// Read returns the contents of the file at path.
+func (f *FilesystemBackend) Read(path string) ([]byte, error) {
+	return ioutil.ReadFile(path)
+}
+
+// Write stores data in the file at path, creating any missing parent
+// directories.
+func (f *FilesystemBackend) Write(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// Delete removes the file at path. It is not an error for path to not exist.
+func (f *FilesystemBackend) Delete(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// PathSeparator returns the local filesystem's path separator.
+func (f *FilesystemBackend) PathSeparator() string {
+	return string(os.PathSeparator)
+}
+
+// fsLock is the contents of a lockfile: who holds it, and until when.
+type fsLock struct {
+	Token   string    `json:"token"`
+	Expires time.Time `json:"expires"`
+}
+
+// AcquireLock creates a lockfile for path. If an existing lockfile is still
+// live, AcquireLock fails; if it's expired, AcquireLock replaces it,
+// treating whoever held it as gone. The initial create is atomic
+// (O_CREATE|O_EXCL), so two concurrent managers racing to create the
+// lockfile for the first time can't both believe they hold it.
+func (f *FilesystemBackend) AcquireLock(path string, ttl time.Duration) (string, error) {
+	lockPath := path + lockSuffix
+
+	token := uuid.New().String()
+	b, err := json.Marshal(fsLock{Token: token, Expires: time.Now().Add(ttl)})
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0755); err != nil {
+		return "", err
+	}
+
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err == nil {
+		defer file.Close()
+		if _, err := file.Write(b); err != nil {
+			return "", err
+		}
+		return token, nil
+	}
+	if !os.IsExist(err) {
+		return "", err
+	}
+
+	// the lockfile already exists; it's only ours to take over if it's expired
+	existingBytes, err := ioutil.ReadFile(lockPath)
+	if err != nil {
+		return "", err
+	}
+	var existing fsLock
+	if err := json.Unmarshal(existingBytes, &existing); err == nil && time.Now().Before(existing.Expires) {
+		return "", fmt.Errorf("backend: %s is locked until %s", path, existing.Expires)
+	}
+	if err := f.Write(lockPath, b); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RenewLock extends the lockfile for path to expire ttl from now, provided
+// token still holds it.
+func (f *FilesystemBackend) RenewLock(path, token string, ttl time.Duration) error {
+	lockPath := path + lockSuffix
+
+	b, err := ioutil.ReadFile(lockPath)
+	if err != nil {
+		return fmt.Errorf("backend: %s has no lock to renew: %v", path, err)
+	}
+
+	var existing fsLock
+	if err := json.Unmarshal(b, &existing); err != nil {
+		return err
+	}
+	if existing.Token != token {
+		return fmt.Errorf("backend: %s is no longer locked by this token", path)
+	}
+
+	renewed, err := json.Marshal(fsLock{Token: token, Expires: time.Now().Add(ttl)})
+	if err != nil {
+		return err
+	}
+	return f.Write(lockPath, renewed)
+}
+
+// ReleaseLock removes the lockfile for path if it's still held by token.
+func (f *FilesystemBackend) ReleaseLock(path, token string) error {
+	lockPath := path + lockSuffix
+
+	b, err := ioutil.ReadFile(lockPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var existing fsLock
+	if err := json.Unmarshal(b, &existing); err != nil {
+		return err
+	}
+	if existing.Token != token {
+		// someone else's lock has already replaced ours (ours expired); leave it alone
+		return nil
+	}
+	return f.Delete(lockPath)
+}
@@ -0,0 +1,196 @@
+package backend
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/google/uuid"
+)
+
+// lockObjectSuffix names the lock object backing AcquireLock for a given
+// path, e.g. "releases/myapp.json.lock".
+const lockObjectSuffix = ".lock"
+
+// S3Backend stores release manager state and releases as objects in an S3
+// bucket.
+type S3Backend struct {
+	Bucket string
+	Client *s3.S3
+}
+
+// NewS3Backend returns an S3Backend for bucket using the default AWS
+// session (standard credential chain, region from the environment/profile).
+func NewS3Backend(bucket string) (*S3Backend, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	return &S3Backend{Bucket: bucket, Client: s3.New(sess)}, nil
+}
+
+// List returns the keys of the objects directly under path, i.e. with no
+// further "/" beyond path's own prefix.
+func (b *S3Backend) List(path string) ([]string, error) {
+	prefix := path + b.PathSeparator()
+	out, err := b.Client.ListObjectsV2(&s3.ListObjectsV2Input{
+		Bucket:    aws.String(b.Bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String(b.PathSeparator()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("backend: error listing %s: %v", path, err)
+	}
+
+	var names []string
+	for _, o := range out.Contents {
+		names = append(names, strings.TrimPrefix(aws.StringValue(o.Key), prefix))
+	}
+	return names, nil
+}
+
+// Read returns the contents of the object at path.
+func (b *S3Backend) Read(path string) ([]byte, error) {
+	out, err := b.Client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("backend: error reading %s: %v", path, err)
+	}
+	defer out.Body.Close()
+	return ioutil.ReadAll(out.Body)
+}
+
+// Write stores data at path, overwriting any existing object.
+func (b *S3Backend) Write(path string, data []byte) error {
+	_, err := b.Client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(path),
+		Body:   aws.ReadSeekCloser(strings.NewReader(string(data))),
+	})
+	if err != nil {
+		return fmt.Errorf("backend: error writing %s: %v", path, err)
+	}
+	return nil
+}
+
+// Delete removes the object at path. It is not an error for path to not
+// exist.
+func (b *S3Backend) Delete(path string) error {
+	_, err := b.Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return fmt.Errorf("backend: error deleting %s: %v", path, err)
+	}
+	return nil
+}
+
+// PathSeparator returns the separator S3 keys use to express a directory
+// hierarchy.
+func (b *S3Backend) PathSeparator() string {
+	return "/"
+}
+
+// AcquireLock writes a lock object at path+".lock" with an If-None-Match:
+// "*" condition, so the write only succeeds if no lock object currently
+// exists. If a stale lock exists (its TTL has elapsed), AcquireLock deletes
+// it and retries once, treating whoever held it as gone.
+func (b *S3Backend) AcquireLock(path string, ttl time.Duration) (string, error) {
+	return b.acquireLock(path, ttl, true)
+}
+
+func (b *S3Backend) acquireLock(path string, ttl time.Duration, retryStale bool) (string, error) {
+	lockKey := path + lockObjectSuffix
+	token := uuid.New().String()
+
+	_, err := b.Client.PutObject(&s3.PutObjectInput{
+		Bucket:      aws.String(b.Bucket),
+		Key:         aws.String(lockKey),
+		Body:        aws.ReadSeekCloser(strings.NewReader(token)),
+		IfNoneMatch: aws.String("*"),
+		Metadata:    map[string]*string{"expires": aws.String(time.Now().Add(ttl).Format(time.RFC3339))},
+	})
+	if err == nil {
+		return token, nil
+	}
+
+	if retryStale && b.lockExpired(lockKey) {
+		if delErr := b.Delete(lockKey); delErr != nil {
+			return "", fmt.Errorf("backend: %s is locked and the stale lock couldn't be cleared: %v", path, delErr)
+		}
+		return b.acquireLock(path, ttl, false)
+	}
+	return "", fmt.Errorf("backend: %s is already locked: %v", path, err)
+}
+
+// lockExpired reports whether the lock object at lockKey has an "expires"
+// metadata value in the past. A lock object that can't be read or is
+// missing the metadata is treated as not expired, so a transient read
+// failure doesn't cause a live lock to be stolen.
+func (b *S3Backend) lockExpired(lockKey string) bool {
+	out, err := b.Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(lockKey),
+	})
+	if err != nil {
+		return false
+	}
+	expires, ok := out.Metadata["expires"]
+	if !ok {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, aws.StringValue(expires))
+	if err != nil {
+		return false
+	}
+	return time.Now().After(t)
+}
+
+// RenewLock extends the lock object at path+".lock" to expire ttl from now,
+// provided token still holds it.
+func (b *S3Backend) RenewLock(path, token string, ttl time.Duration) error {
+	lockKey := path + lockObjectSuffix
+
+	held, err := b.Read(lockKey)
+	if err != nil {
+		return fmt.Errorf("backend: %s has no lock to renew: %v", path, err)
+	}
+	if string(held) != token {
+		return fmt.Errorf("backend: %s is no longer locked by this token", path)
+	}
+
+	_, err = b.Client.PutObject(&s3.PutObjectInput{
+		Bucket:   aws.String(b.Bucket),
+		Key:      aws.String(lockKey),
+		Body:     aws.ReadSeekCloser(strings.NewReader(token)),
+		Metadata: map[string]*string{"expires": aws.String(time.Now().Add(ttl).Format(time.RFC3339))},
+	})
+	if err != nil {
+		return fmt.Errorf("backend: error renewing lock for %s: %v", path, err)
+	}
+	return nil
+}
+
+// ReleaseLock removes the lock object at path+".lock" if it's still held by
+// token.
+func (b *S3Backend) ReleaseLock(path, token string) error {
+	lockKey := path + lockObjectSuffix
+
+	held, err := b.Read(lockKey)
+	if err != nil {
+		// already gone (expired and cleared, or never existed)
+		return nil
+	}
+	if string(held) != token {
+		// someone else's lock has already replaced ours; leave it alone
+		return nil
+	}
+	return b.Delete(lockKey)
+}
@@ -0,0 +1,84 @@
+// Package config holds the release manager's runtime configuration. The CLI
+// populates a single *Config from flags/viper in cmd/ and threads it through
+// every library package (state, transfer, export, rollback, uninstall)
+// instead of each package reading global flag state itself.
+package config
+
+import (
+	"time"
+
+	"github.com/logicmonitor/k8s-release-manager/pkg/client"
+)
+
+// Config is the release manager's runtime configuration. DryRun and
+// VerboseMode apply across every command; Backend and Helm are always
+// populated; the command-specific sub-configs (Export, Transfer, Rollback,
+// Uninstall) are nil until the corresponding command's PreRun sets them.
+type Config struct {
+	ClusterConfig *client.Config
+	Backend       BackendConfig
+	Helm          HelmConfig
+	DryRun        bool
+	VerboseMode   bool
+
+	Export    *ExportConfig
+	Transfer  *TransferConfig
+	Rollback  *RollbackConfig
+	Uninstall *UninstallConfig
+}
+
+// BackendConfig configures the backend.Backend storage driver, including
+// the distributed lock every state-mutating operation acquires before
+// reading or writing at StoragePath.
+type BackendConfig struct {
+	// StoragePath is the backend-relative path release manager state and
+	// releases are read from and written to.
+	StoragePath string
+	// LockTTL is how long a single AcquireLock grants exclusive access to
+	// StoragePath before it expires.
+	LockTTL time.Duration
+	// LockWait is how long to retry AcquireLock before failing.
+	LockWait time.Duration
+}
+
+// HelmConfig configures the Tiller connection used by lmhelm.Client.
+type HelmConfig struct {
+	// ReleaseTimeoutSec is how long, in seconds, to wait for an individual
+	// Helm release operation to complete.
+	ReleaseTimeoutSec int64
+}
+
+// ExportConfig configures the export command
+type ExportConfig struct {
+	DaemonMode      bool
+	ReleaseName     string
+	PollingInterval int64
+	Namespaces      map[string]string
+}
+
+// TransferConfig configures the import command (Transfer drives both import
+// and the historical transfer flow; see pkg/action's package doc)
+type TransferConfig struct {
+	Force             bool
+	NewStoragePath    string
+	Installer         string
+	RudderAddress     string
+	OnExists          string
+	DiffOnly          bool
+	Namespace         string
+	ExcludeNamespaces []string
+}
+
+// RollbackConfig configures the rollback command
+type RollbackConfig struct {
+	ReleaseName       string
+	Namespace         string
+	ExcludeNamespaces []string
+	Revision          int32
+	Force             bool
+}
+
+// UninstallConfig configures the uninstall command
+type UninstallConfig struct {
+	ReleaseName string
+}
@@ -0,0 +1,127 @@
+package export
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/logicmonitor/k8s-release-manager/pkg/client"
+	"github.com/logicmonitor/k8s-release-manager/pkg/config"
+	"github.com/logicmonitor/k8s-release-manager/pkg/lmhelm"
+	rlog "github.com/logicmonitor/k8s-release-manager/pkg/log"
+	"github.com/logicmonitor/k8s-release-manager/pkg/state"
+	rls "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// Export collects every release currently deployed to the cluster and
+// writes it to the configured backend, so a later Import/Transfer can
+// recreate them in a different cluster.
+type Export struct {
+	Config     *config.Config
+	HelmClient lmhelm.Interface
+	State      *state.State
+	// Log is called for informational and error messages produced while
+	// exporting. It defaults to a no-op if left unset.
+	Log rlog.Logger
+}
+
+// New instantiates and returns an Export and an error if any. A nil logger
+// defaults to a no-op.
+func New(rlsmgrconfig *config.Config, state *state.State, logger rlog.Logger) (*Export, error) {
+	helmClient := &lmhelm.Client{}
+
+	kubernetesClient, kubernetesConfig, err := client.KubernetesClient(rlsmgrconfig.ClusterConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	err = helmClient.Init(rlsmgrconfig.Helm, kubernetesClient, kubernetesConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if logger == nil {
+		logger = rlog.Nop
+	}
+	return &Export{
+		Config:     rlsmgrconfig,
+		HelmClient: helmClient,
+		State:      state,
+		Log:        logger,
+	}, nil
+}
+
+// Run the Export. In daemon mode it blocks, re-exporting the current state
+// every --polling-interval until the process is stopped; otherwise it
+// exports once and returns.
+func (e *Export) Run() error {
+	if err := e.State.Init(); err != nil {
+		return err
+	}
+
+	if err := e.export(); err != nil {
+		return err
+	}
+	if !e.Config.Export.DaemonMode {
+		return nil
+	}
+
+	ticker := time.NewTicker(time.Duration(e.Config.Export.PollingInterval) * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := e.export(); err != nil {
+			e.logf(rlog.Error, "Error exporting state: %v", err)
+		}
+	}
+	return nil
+}
+
+func (e *Export) export() error {
+	releases, err := e.HelmClient.List()
+	if err != nil {
+		return fmt.Errorf("Error listing releases: %v", err)
+	}
+
+	releases = e.filterReleasesByNamespace(releases)
+
+	if e.Config.DryRun {
+		for _, r := range releases {
+			fmt.Printf("Would export release %s\n", r.GetName())
+		}
+		return nil
+	}
+
+	for _, r := range releases {
+		if err := e.State.WriteRelease(r); err != nil {
+			e.logf(rlog.Error, "Error writing release %s: %v", r.GetName(), err)
+		}
+	}
+	return e.State.Update(releases)
+}
+
+// filterReleasesByNamespace restricts releases to the namespaces configured
+// via --namespaces. An unset (empty) configured set exports every namespace;
+// so does a set holding only "", which is what an unset --namespaces parses to.
+func (e *Export) filterReleasesByNamespace(releases []*rls.Release) []*rls.Release {
+	if len(e.Config.Export.Namespaces) == 0 {
+		return releases
+	}
+	if _, unset := e.Config.Export.Namespaces[""]; unset && len(e.Config.Export.Namespaces) == 1 {
+		return releases
+	}
+	var filtered []*rls.Release
+	for _, r := range releases {
+		if _, ok := e.Config.Export.Namespaces[r.GetNamespace()]; ok {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// logf calls the configured Logger, falling back to a no-op if none was set
+func (e *Export) logf(level rlog.Level, format string, args ...interface{}) {
+	if e.Log == nil {
+		rlog.Nop(level, format, args...)
+		return
+	}
+	e.Log(level, format, args...)
+}
@@ -0,0 +1,33 @@
+package lmhelm
+
+import (
+	"errors"
+
+	rls "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// ErrReleaseNotFound is returned by ReleaseStatus when no release with the
+// requested name is currently deployed.
+var ErrReleaseNotFound = errors.New("lmhelm: release not found")
+
+// ErrorReleaseNotFound reports whether err is, or wraps, ErrReleaseNotFound.
+func ErrorReleaseNotFound(err error) bool {
+	return errors.Is(err, ErrReleaseNotFound)
+}
+
+// Interface is the subset of Helm operations the release manager needs to
+// install, upgrade, roll back, uninstall, and enumerate releases. Client
+// implements Interface against a live Tiller; tests and other consumers
+// that only need to exercise the control flow around it can substitute
+// their own implementation, e.g. the generated mock in internal/mocks.
+type Interface interface {
+	Install(r *rls.Release) error
+	Upgrade(r *rls.Release) error
+	Rollback(r *rls.Release) error
+	Uninstall(name string) error
+	List() ([]*rls.Release, error)
+	// ReleaseStatus returns the currently deployed release named name. It
+	// returns an error satisfying ErrorReleaseNotFound if no such release
+	// is deployed.
+	ReleaseStatus(name string) (*rls.Release, error)
+}
@@ -0,0 +1,33 @@
+// Package log defines the generic logging interface shared by the release
+// manager's library packages.
+package log
+
+// Level indicates how important a message is, so a Logger backed by a
+// leveled logging library (e.g. logrus) can decide which messages an
+// operator sees by default and which are reserved for debug output.
+type Level int
+
+// Debug is for internal diagnostic detail hidden unless debug logging is
+// enabled. Info, Warn, and Error are progressively more severe and are
+// expected to be visible by default.
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+)
+
+// Logger is a leveled logging function. logrus's Debugf/Infof/Warnf/Errorf
+// functions do not take a Level and so are not Loggers themselves; an
+// adapter that switches on level and calls the matching one is (see
+// logrusLogger in cmd). Library packages accept a Logger
+// instead of depending on logrus directly so that consumers (e.g. an
+// operator embedding these packages in its own controller) aren't forced
+// to inherit our logging configuration or logrus's global state, while the
+// Level argument preserves which messages should stay visible without
+// debug logging enabled.
+type Logger func(level Level, format string, args ...interface{})
+
+// Nop is a Logger that discards every message. It's the default for
+// packages that haven't been given an explicit Logger.
+func Nop(level Level, format string, args ...interface{}) {}
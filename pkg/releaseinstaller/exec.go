@@ -0,0 +1,160 @@
+package releaseinstaller
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/logicmonitor/k8s-release-manager/pkg/lmhelm"
+	rls "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// notFoundMarker is the substring `helm status` prints to stderr for a
+// release that doesn't exist, distinguishing that case from an operational
+// failure (missing binary, bad kubeconfig, a Tiller/cluster that's
+// unreachable) that status can't distinguish from "not deployed yet" any
+// other way.
+const notFoundMarker = "not found"
+
+// ExecInstaller drives a local helm binary directly, for Helm 3 users who no
+// longer run Tiller. The same exported release state that HelmInstaller
+// would install against Tiller can instead be replayed via
+// `helm upgrade --install`.
+type ExecInstaller struct {
+	// HelmBin is the path to the helm binary to invoke. Defaults to "helm".
+	HelmBin string
+}
+
+// Install deploys a release that doesn't yet exist
+func (e *ExecInstaller) Install(r *rls.Release) error {
+	return e.upgradeInstall(r)
+}
+
+// Upgrade deploys a new revision of an already-installed release. helm
+// upgrade --install is idempotent, so this is identical to Install.
+func (e *ExecInstaller) Upgrade(r *rls.Release) error {
+	return e.upgradeInstall(r)
+}
+
+// Rollback reverts a release to a previously stored revision
+func (e *ExecInstaller) Rollback(r *rls.Release) error {
+	return e.run("rollback", r.GetName(), fmt.Sprintf("%d", r.GetVersion()))
+}
+
+// Uninstall removes a release
+func (e *ExecInstaller) Uninstall(r *rls.Release) error {
+	return e.run("uninstall", r.GetName())
+}
+
+// Status reports whether name is currently deployed. helm status exits
+// non-zero both for an unknown release and for operational failures
+// (missing helm binary, bad kubeconfig, unreachable cluster); only the
+// former should be treated as lmhelm.ErrReleaseNotFound, so a real failure
+// isn't silently routed into Install as if the release just wasn't
+// deployed yet.
+//
+// The returned Release only carries enough to answer "does it exist",
+// since `helm status` doesn't expose the deployed chart/values. That makes
+// it unsuitable for --diff-only, which transfer.New refuses to combine with
+// --installer=exec rather than diffing against this placeholder.
+func (e *ExecInstaller) Status(name string) (*rls.Release, error) {
+	err := e.run("status", name)
+	if err == nil {
+		return &rls.Release{Name: name}, nil
+	}
+	if strings.Contains(err.Error(), notFoundMarker) {
+		return nil, lmhelm.ErrReleaseNotFound
+	}
+	return nil, fmt.Errorf("Error checking status of release %s: %v", name, err)
+}
+
+func (e *ExecInstaller) upgradeInstall(r *rls.Release) error {
+	valuesFile, err := e.writeValues(r)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(valuesFile)
+
+	chartDir, err := e.writeChart(r)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(chartDir)
+
+	return e.run("upgrade", "--install", r.GetName(), chartDir,
+		"--namespace", r.GetNamespace(), "--values", valuesFile)
+}
+
+// writeChart materializes r's stored chart into a temp directory helm can
+// install directly, since a bare chart name means "look this up in a repo",
+// which is meaningless here: the chart being deployed is whatever was
+// exported, not whatever currently has that name in some repo. It writes
+// Chart.yaml, the chart's default values.yaml, and its templates; the
+// chart's other files and its dependencies are not written.
+func (e *ExecInstaller) writeChart(r *rls.Release) (string, error) {
+	dir, err := ioutil.TempDir("", fmt.Sprintf("%s-chart-", r.GetName()))
+	if err != nil {
+		return "", err
+	}
+
+	meta := r.GetChart().GetMetadata()
+	chartYaml := fmt.Sprintf("name: %s\nversion: %s\n", meta.GetName(), meta.GetVersion())
+	if err := ioutil.WriteFile(filepath.Join(dir, "Chart.yaml"), []byte(chartYaml), 0644); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "values.yaml"), []byte(r.GetChart().GetValues().GetRaw()), 0644); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	// Template.Name already carries its path relative to the chart root
+	// (e.g. "templates/deployment.yaml"), matching how Helm's own chart
+	// loader populates it.
+	for _, tpl := range r.GetChart().GetTemplates() {
+		path := filepath.Join(dir, tpl.GetName())
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+		if err := ioutil.WriteFile(path, tpl.GetData(), 0644); err != nil {
+			os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+func (e *ExecInstaller) writeValues(r *rls.Release) (string, error) {
+	f, err := ioutil.TempFile("", fmt.Sprintf("%s-values-*.yaml", r.GetName()))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(r.GetConfig().GetRaw()); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func (e *ExecInstaller) bin() string {
+	if e.HelmBin == "" {
+		return "helm"
+	}
+	return e.HelmBin
+}
+
+func (e *ExecInstaller) run(args ...string) error {
+	cmd := exec.Command(e.bin(), args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("helm %v: %v: %s", args, err, stderr.String())
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package releaseinstaller
+
+import (
+	"fmt"
+
+	"github.com/logicmonitor/k8s-release-manager/pkg/lmhelm"
+)
+
+// Helm, Rudder, and Exec name the --installer values New understands. Rudder
+// is recognized but not yet usable: see NewRudderInstaller.
+const (
+	Helm   = "helm"
+	Rudder = "rudder"
+	Exec   = "exec"
+)
+
+// New builds the Installer selected by kind (one of Helm, Rudder, Exec). An
+// empty kind defaults to Helm, installing directly against the in-cluster
+// Tiller via helmClient. rudderAddress is only consulted when kind is
+// Rudder. Rudder itself currently always returns an error; see
+// NewRudderInstaller.
+func New(kind, rudderAddress string, helmClient lmhelm.Interface) (Installer, error) {
+	switch kind {
+	case "", Helm:
+		return &HelmInstaller{Client: helmClient}, nil
+	case Rudder:
+		return NewRudderInstaller(rudderAddress)
+	case Exec:
+		return &ExecInstaller{}, nil
+	default:
+		return nil, fmt.Errorf("Unknown installer %q. Must be one of %s, %s, %s", kind, Helm, Rudder, Exec)
+	}
+}
@@ -0,0 +1,37 @@
+package releaseinstaller
+
+import (
+	"github.com/logicmonitor/k8s-release-manager/pkg/lmhelm"
+	rls "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// HelmInstaller installs releases directly against an in-cluster Tiller via
+// lmhelm.Client. It's the default Installer, selected by --installer=helm.
+type HelmInstaller struct {
+	Client lmhelm.Interface
+}
+
+// Install deploys a release that doesn't yet exist
+func (h *HelmInstaller) Install(r *rls.Release) error {
+	return h.Client.Install(r)
+}
+
+// Upgrade deploys a new revision of an already-installed release
+func (h *HelmInstaller) Upgrade(r *rls.Release) error {
+	return h.Client.Upgrade(r)
+}
+
+// Rollback reverts a release to a previously stored revision
+func (h *HelmInstaller) Rollback(r *rls.Release) error {
+	return h.Client.Rollback(r)
+}
+
+// Uninstall removes a release
+func (h *HelmInstaller) Uninstall(r *rls.Release) error {
+	return h.Client.Uninstall(r.GetName())
+}
+
+// Status returns the release currently deployed under name
+func (h *HelmInstaller) Status(name string) (*rls.Release, error) {
+	return h.Client.ReleaseStatus(name)
+}
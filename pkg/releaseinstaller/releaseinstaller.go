@@ -0,0 +1,27 @@
+// Package releaseinstaller abstracts how a stored release is actually
+// deployed, so that importing and transferring exported state doesn't have
+// to assume a live Tiller is reachable in the target cluster.
+package releaseinstaller
+
+import (
+	rls "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// Installer performs release lifecycle operations on behalf of the release
+// manager. HelmInstaller talks to an in-cluster Tiller; ExecInstaller drives a
+// local Helm 3 binary instead. RudderInstaller returns an error from every
+// method and does not contact a Rudder service.
+type Installer interface {
+	Install(r *rls.Release) error
+	Upgrade(r *rls.Release) error
+	Rollback(r *rls.Release) error
+	Uninstall(r *rls.Release) error
+	// Status returns the release currently deployed under name. It returns
+	// an error satisfying lmhelm.ErrorReleaseNotFound if no such release is
+	// deployed. Routing the existence check through the selected Installer,
+	// rather than always through a Tiller-backed lmhelm.Interface, keeps
+	// --installer=exec from querying Tiller for release status. transfer.New
+	// still initializes the Tiller-backed lmhelm.Client for every installer,
+	// including exec.
+	Status(name string) (*rls.Release, error)
+}
@@ -0,0 +1,60 @@
+package releaseinstaller
+
+import (
+	"fmt"
+
+	rls "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// RudderInstaller would perform install/upgrade/rollback/uninstall by
+// dialing a Rudder-compatible gRPC ReleaseModuleService, mirroring Helm's
+// own Rudder split: the caller serializes the release and Rudder performs
+// the actual Kubernetes operations on its behalf, so the release manager
+// doesn't need direct Tiller access.
+//
+// It isn't implemented: the generated Rudder proto client isn't vendored in
+// this module, so there's nothing here to dial or call yet. RudderInstaller
+// only exists so --installer=rudder has somewhere to fail with a clear
+// message instead of releaseinstaller.New rejecting the flag outright.
+type RudderInstaller struct {
+	Address string
+}
+
+// NewRudderInstaller always returns an error; see the RudderInstaller doc
+// comment.
+func NewRudderInstaller(address string) (*RudderInstaller, error) {
+	return nil, fmt.Errorf("releaseinstaller: rudder installer is not yet implemented (no Rudder proto client vendored)")
+}
+
+// Install deploys a release that doesn't yet exist
+func (r *RudderInstaller) Install(rel *rls.Release) error {
+	return r.notImplemented("InstallRelease", rel.GetName())
+}
+
+// Upgrade deploys a new revision of an already-installed release
+func (r *RudderInstaller) Upgrade(rel *rls.Release) error {
+	return r.notImplemented("UpgradeRelease", rel.GetName())
+}
+
+// Rollback reverts a release to a previously stored revision
+func (r *RudderInstaller) Rollback(rel *rls.Release) error {
+	return r.notImplemented("RollbackRelease", rel.GetName())
+}
+
+// Uninstall removes a release
+func (r *RudderInstaller) Uninstall(rel *rls.Release) error {
+	return r.notImplemented("UninstallRelease", rel.GetName())
+}
+
+// Status returns the release currently deployed under name. Like the other
+// RudderInstaller methods, this can never actually run today: see the
+// RudderInstaller doc comment.
+func (r *RudderInstaller) Status(name string) (*rls.Release, error) {
+	return nil, r.notImplemented("GetReleaseStatus", name)
+}
+
+// notImplemented is what every RudderInstaller method returns: none of them
+// can do anything real until the Rudder proto client is vendored.
+func (r *RudderInstaller) notImplemented(method, releaseName string) error {
+	return fmt.Errorf("releaseinstaller: Rudder %s is not yet implemented (release %s, addr %s)", method, releaseName, r.Address)
+}
@@ -0,0 +1,149 @@
+package rollback
+
+import (
+	"fmt"
+
+	"github.com/logicmonitor/k8s-release-manager/pkg/client"
+	"github.com/logicmonitor/k8s-release-manager/pkg/config"
+	"github.com/logicmonitor/k8s-release-manager/pkg/lmhelm"
+	rlog "github.com/logicmonitor/k8s-release-manager/pkg/log"
+	"github.com/logicmonitor/k8s-release-manager/pkg/state"
+	rls "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// Rollback reverts a previously imported release to an earlier stored revision
+type Rollback struct {
+	Config     *config.Config
+	HelmClient lmhelm.Interface
+	State      *state.State
+	// Log is called for informational and error messages produced while
+	// rolling back. It defaults to a no-op if left unset.
+	Log rlog.Logger
+}
+
+// New instantiates and returns a Rollback and an error if any. A nil logger
+// defaults to a no-op.
+func New(rlsmgrconfig *config.Config, state *state.State, logger rlog.Logger) (*Rollback, error) {
+	helmClient := &lmhelm.Client{}
+
+	kubernetesClient, kubernetesConfig, err := client.KubernetesClient(rlsmgrconfig.ClusterConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	err = helmClient.Init(rlsmgrconfig.Helm, kubernetesClient, kubernetesConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if logger == nil {
+		logger = rlog.Nop
+	}
+	return &Rollback{
+		Config:     rlsmgrconfig,
+		HelmClient: helmClient,
+		State:      state,
+		Log:        logger,
+	}, nil
+}
+
+// Run the Rollback
+func (b *Rollback) Run() error {
+	revisions, err := b.State.StoredRevisions(b.Config.Rollback.ReleaseName)
+	if err != nil {
+		return err
+	}
+
+	revisions = b.filterRevisionsByNamespace(revisions)
+	if len(revisions) == 0 {
+		return fmt.Errorf("No stored revisions found for release %s", b.Config.Rollback.ReleaseName)
+	}
+
+	target, err := b.targetRevision(revisions)
+	if err != nil {
+		return err
+	}
+
+	if b.Config.DryRun {
+		fmt.Printf("Would roll back release %s to revision %d\n", target.GetName(), target.GetVersion())
+		return nil
+	}
+
+	return b.rollbackRelease(target)
+}
+
+// filterRevisionsByNamespace honors --namespace (include only that
+// namespace) and --exclude-namespaces (roll back anything except those
+// namespaces), the same filter semantics Import/Transfer support;
+// --namespace takes precedence if both are set.
+func (b *Rollback) filterRevisionsByNamespace(revisions []*rls.Release) []*rls.Release {
+	if b.Config.Rollback.Namespace != "" {
+		var filtered []*rls.Release
+		for _, r := range revisions {
+			if r.Namespace == b.Config.Rollback.Namespace {
+				filtered = append(filtered, r)
+			}
+		}
+		return filtered
+	}
+
+	if len(b.Config.Rollback.ExcludeNamespaces) > 0 {
+		var filtered []*rls.Release
+		for _, r := range revisions {
+			excluded := false
+			for _, namespace := range b.Config.Rollback.ExcludeNamespaces {
+				if r.Namespace == namespace {
+					excluded = true
+					break
+				}
+			}
+			if !excluded {
+				filtered = append(filtered, r)
+			}
+		}
+		return filtered
+	}
+
+	return revisions
+}
+
+// targetRevision picks the release to roll back to, honoring --revision or
+// defaulting to the revision immediately prior to the most recently stored one
+func (b *Rollback) targetRevision(revisions []*rls.Release) (*rls.Release, error) {
+	if b.Config.Rollback.Revision != 0 {
+		for _, r := range revisions {
+			if r.GetVersion() == b.Config.Rollback.Revision {
+				return r, nil
+			}
+		}
+		return nil, fmt.Errorf("Revision %d not found for release %s", b.Config.Rollback.Revision, b.Config.Rollback.ReleaseName)
+	}
+
+	if len(revisions) < 2 {
+		return nil, fmt.Errorf("No previous revision available for release %s", b.Config.Rollback.ReleaseName)
+	}
+	return revisions[len(revisions)-2], nil
+}
+
+func (b *Rollback) rollbackRelease(r *rls.Release) error {
+	fmt.Printf("Rolling back release %s to revision %d\n", r.GetName(), r.GetVersion())
+	err := b.HelmClient.Rollback(r)
+	if err != nil {
+		if !b.Config.Rollback.Force {
+			return fmt.Errorf("Error rolling back release %s: %v", r.GetName(), err)
+		}
+		b.logf(rlog.Warn, "Error rolling back release %s: %v. --force specified, continuing.", r.GetName(), err)
+		return nil
+	}
+	fmt.Printf("Successfully rolled back release %s\n", r.GetName())
+	return nil
+}
+
+// logf calls the configured Logger, falling back to a no-op if none was set
+func (b *Rollback) logf(level rlog.Level, format string, args ...interface{}) {
+	if b.Log == nil {
+		rlog.Nop(level, format, args...)
+		return
+	}
+	b.Log(level, format, args...)
+}
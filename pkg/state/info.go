@@ -0,0 +1,17 @@
+package state
+
+import "encoding/json"
+
+// Info is the release manager's own tracked state: which stored release
+// file backs the manager's release, and at what version, so Update can
+// tell whether the tracked release has moved on to a new revision.
+type Info struct {
+	ReleaseFilename string `json:"release_filename"`
+	ReleaseName     string `json:"release_name"`
+	ReleaseVersion  int32  `json:"release_version"`
+}
+
+// Serialize marshals i to its on-backend JSON representation.
+func (i *Info) Serialize() ([]byte, error) {
+	return json.Marshal(i)
+}
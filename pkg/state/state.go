@@ -5,21 +5,35 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/logicmonitor/k8s-release-manager/pkg/backend"
 	"github.com/logicmonitor/k8s-release-manager/pkg/config"
 	"github.com/logicmonitor/k8s-release-manager/pkg/constants"
+	rlog "github.com/logicmonitor/k8s-release-manager/pkg/log"
 	"github.com/logicmonitor/k8s-release-manager/pkg/release"
-	log "github.com/sirupsen/logrus"
 	rls "k8s.io/helm/pkg/proto/hapi/release"
 )
 
+// lockRetryInterval is how long withLock waits between AcquireLock attempts
+// while it's still within the configured lock-wait window
+const lockRetryInterval = time.Second
+
 // State represents the release manager's state information
 type State struct {
 	Backend backend.Backend
 	Config  *config.Config
-	init    bool
+	// Log is called for informational and error messages produced while
+	// working with state. It defaults to a no-op if left unset.
+	Log rlog.Logger
+	// Info caches the release manager state last read from the backend, so
+	// callers like pkg/transfer can check it (e.g. "is this release the one
+	// tracked in state") without issuing their own read. It's nil until Read
+	// succeeds at least once.
+	Info *Info
+	init bool
 }
 
 // Init the release manager state
@@ -27,10 +41,10 @@ func (s *State) Init() error {
 	s.init = false
 	if s.Config.Export.ReleaseName != "" {
 		path := s.remoteFilePath(constants.ManagerStateFilename)
-		log.Infof("Removing old state %s", path)
+		s.logf(rlog.Info, "Removing old state %s", path)
 		err := s.Backend.Delete(path)
 		if err != nil {
-			log.Warnf("Error cleaning up old release manager state: %v", err)
+			s.logf(rlog.Warn, "Error cleaning up old release manager state: %v", err)
 		}
 	}
 	return nil
@@ -39,24 +53,87 @@ func (s *State) Init() error {
 // Update updates the release manager state on the backend
 func (s *State) Update(releases []*rls.Release) error {
 	if s.Config.Export.ReleaseName == "" {
-		log.Debugf("--release-name not specified. Ignoring state.")
+		s.logf(rlog.Debug, "--release-name not specified. Ignoring state.")
 		return nil
 	}
 
-	// locate the release managing this application
-	for _, r := range releases {
-		if s.isManagerRelease(r.GetName()) {
-			return s.updateState(&Info{
-				ReleaseFilename: release.Filename(r),
-				ReleaseName:     s.Config.Export.ReleaseName,
-				ReleaseVersion:  r.GetVersion(),
-			})
+	return s.withLock(func() error {
+		// locate the release managing this application
+		for _, r := range releases {
+			if s.isManagerRelease(r.GetName()) {
+				return s.updateState(&Info{
+					ReleaseFilename: release.Filename(r),
+					ReleaseName:     s.Config.Export.ReleaseName,
+					ReleaseVersion:  r.GetVersion(),
+				})
+			}
+		}
+
+		// if the manager release no longer exists, delete the remote state
+		s.logf(rlog.Debug, "Release manager release %s doesn't exist. Removing state.", s.Config.Export.ReleaseName)
+		return s.delete()
+	})
+}
+
+// withLock acquires the backend lock scoped to the configured storage path,
+// retrying until --lock-wait elapses, runs fn while holding it, and always
+// releases it afterward. While fn runs, the lock is renewed at half its TTL
+// so a slow write (or, in daemon mode, a fn that ends up running close to
+// the next poll) doesn't let the lock expire out from under it.
+func (s *State) withLock(fn func() error) error {
+	path := s.Config.Backend.StoragePath
+	deadline := time.Now().Add(s.Config.Backend.LockWait)
+
+	var token string
+	var err error
+	for {
+		token, err = s.Backend.AcquireLock(path, s.Config.Backend.LockTTL)
+		if err == nil {
+			break
 		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Unable to acquire backend lock for %s after %s: %v", path, s.Config.Backend.LockWait, err)
+		}
+		time.Sleep(lockRetryInterval)
 	}
 
-	// if the manager release no longer exists, delete the remote state
-	log.Debugf("Release manager release %s doesn't exist. Removing state.", s.Config.Export.ReleaseName)
-	return s.delete()
+	stop := s.renewLock(path, token)
+	defer func() {
+		stop()
+		if e := s.Backend.ReleaseLock(path, token); e != nil {
+			s.logf(rlog.Warn, "Error releasing backend lock for %s: %v", path, e)
+		}
+	}()
+
+	return fn()
+}
+
+// renewLock extends the held lock at half its TTL until the returned stop
+// func is called. A renewal error is logged, not returned, since the
+// in-flight operation under the lock should still get a chance to finish.
+func (s *State) renewLock(path, token string) func() {
+	ttl := s.Config.Backend.LockTTL
+	if ttl <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(ttl / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := s.Backend.RenewLock(path, token, ttl); err != nil {
+					s.logf(rlog.Warn, "Error renewing backend lock for %s: %v. A concurrent manager may take over the lock.", path, err)
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
 }
 
 // Read the release manager state from the backend
@@ -72,7 +149,7 @@ func (s *State) Remove() error {
 // Exists returns true if the remote state file exists
 func (s *State) Exists() (bool, error) {
 	path := s.Path()
-	log.Infof("Check if remote state file %s exists", path)
+	s.logf(rlog.Info, "Check if remote state file %s exists", path)
 	f, err := s.Backend.List(path)
 	if err != nil {
 		return false, err
@@ -100,7 +177,7 @@ func (s *State) updateState(i *Info) (err error) {
 		// check to see if the state is stale
 		oldInfo, e := s.read()
 		if e != nil {
-			log.Warnf("Error reading remote state: %v", e)
+			s.logf(rlog.Warn, "Error reading remote state: %v", e)
 			update = true
 		}
 
@@ -112,7 +189,7 @@ func (s *State) updateState(i *Info) (err error) {
 	}
 
 	if update || !s.init {
-		log.Debugf("Updating state %s.", i.ReleaseName)
+		s.logf(rlog.Debug, "Updating state %s.", i.ReleaseName)
 		err = s.write(i)
 		if err != nil {
 			return
@@ -123,15 +200,18 @@ func (s *State) updateState(i *Info) (err error) {
 
 func (s *State) read() (i *Info, err error) {
 	path := s.Path()
-	log.Debugf("Reading state from %s", path)
+	s.logf(rlog.Debug, "Reading state from %s", path)
 	f, err := s.Backend.Read(path)
 	if err != nil {
 		return nil, err
 	}
 
 	i = &Info{}
-	err = json.Unmarshal(f, i)
-	return i, err
+	if err = json.Unmarshal(f, i); err != nil {
+		return nil, err
+	}
+	s.Info = i
+	return i, nil
 }
 
 func (s *State) write(i *Info) error {
@@ -144,7 +224,7 @@ func (s *State) write(i *Info) error {
 
 func (s *State) delete() error {
 	path := s.Path()
-	log.Debugf("Removing remote state %s", path)
+	s.logf(rlog.Debug, "Removing remote state %s", path)
 	return s.Backend.Delete(path)
 }
 
@@ -159,7 +239,7 @@ func (s *State) isManagerRelease(name string) bool {
 // ReadRelease returns the remote release represented by the specified filename
 func (s *State) ReadRelease(f string) (*rls.Release, error) {
 	path := s.remoteFilePath(f)
-	log.Debugf("Reading remote release %s", path)
+	s.logf(rlog.Debug, "Reading remote release %s", path)
 	b, err := s.Backend.Read(path)
 	if err != nil {
 		return nil, err
@@ -167,23 +247,36 @@ func (s *State) ReadRelease(f string) (*rls.Release, error) {
 	return release.FromFile(b)
 }
 
-// WriteRelease writes the specified release to the backend
+// WriteRelease writes the specified release to the backend, keeping a
+// per-revision copy under the revisions subdirectory in addition to the
+// latest version so that earlier revisions remain available via
+// StoredRevisions
 func (s *State) WriteRelease(r *rls.Release) error {
 	f, err := release.ToFile(r)
 	if err != nil {
 		return err
 	}
 
-	path := s.remoteFilePath(release.Filename(r))
-	log.Debugf("Writing remote release %s", path)
-	return s.Backend.Write(path, f)
+	return s.withLock(func() error {
+		path := s.remoteFilePath(release.Filename(r))
+		s.logf(rlog.Debug, "Writing remote release %s", path)
+		if err := s.Backend.Write(path, f); err != nil {
+			return err
+		}
+
+		revPath := s.revisionFilePath(r.GetName(), r.GetVersion())
+		s.logf(rlog.Debug, "Writing remote release revision %s", revPath)
+		return s.Backend.Write(revPath, f)
+	})
 }
 
 // DeleteRelease deletes the remote release represented by the specified filename
 func (s *State) DeleteRelease(f string) error {
-	path := s.remoteFilePath(f)
-	log.Debugf("Removing remote release %s", path)
-	return s.Backend.Delete(path)
+	return s.withLock(func() error {
+		path := s.remoteFilePath(f)
+		s.logf(rlog.Debug, "Removing remote release %s", path)
+		return s.Backend.Delete(path)
+	})
 }
 
 // StoredReleases returns the list of release structs currently stored in the backend
@@ -200,7 +293,7 @@ func (s *State) StoredReleases() (ret []*rls.Release, err error) {
 			defer wg.Done()
 			r, e := s.ReadRelease(f)
 			if e != nil {
-				log.Warnf("%v", e)
+				s.logf(rlog.Warn, "%v", e)
 				return
 			}
 			*ret = append(*ret, r)
@@ -212,7 +305,7 @@ func (s *State) StoredReleases() (ret []*rls.Release, err error) {
 
 // StoredReleaseNames returns the list of release filenames currently stored in the backend
 func (s *State) StoredReleaseNames() (ret []string, err error) {
-	log.Debugf("Finding releases stored in the backend.")
+	s.logf(rlog.Debug, "Finding releases stored in the backend.")
 	names, err := s.Backend.List(s.Config.Backend.StoragePath)
 	if err != nil {
 		return ret, err
@@ -224,6 +317,9 @@ func (s *State) StoredReleaseNames() (ret []string, err error) {
 		return nil, err
 	}
 
+	// per-revision copies live under revisionsDir, not alongside the
+	// canonical release, so they're never present in names and don't need
+	// to be filtered back out here; see revisionFilePath.
 	for _, n := range names {
 		if r.MatchString(n) {
 			ret = append(ret, n)
@@ -232,6 +328,68 @@ func (s *State) StoredReleaseNames() (ret []string, err error) {
 	return ret, err
 }
 
+// StoredRevisions returns every stored revision of the named release, ordered
+// oldest to newest, so that rollback can pick a target revision
+func (s *State) StoredRevisions(name string) (ret []*rls.Release, err error) {
+	s.logf(rlog.Debug, "Finding stored revisions for release %s.", name)
+	dir := s.revisionsDir()
+	names, err := s.Backend.List(dir)
+	if err != nil {
+		return ret, err
+	}
+
+	r, err := regexp.Compile(fmt.Sprintf("^%s-v[0-9]+%s$", regexp.QuoteMeta(name), constants.ReleaseExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, n := range names {
+		if !r.MatchString(n) {
+			continue
+		}
+		path := dir + s.Backend.PathSeparator() + n
+		s.logf(rlog.Debug, "Reading remote release revision %s", path)
+		b, e := s.Backend.Read(path)
+		if e != nil {
+			s.logf(rlog.Warn, "%v", e)
+			continue
+		}
+		rev, e := release.FromFile(b)
+		if e != nil {
+			s.logf(rlog.Warn, "%v", e)
+			continue
+		}
+		ret = append(ret, rev)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].GetVersion() < ret[j].GetVersion()
+	})
+	return ret, nil
+}
+
+// revisionsSubdir is the backend-relative directory per-revision release
+// copies are written under, keeping them out of the canonical release
+// listing entirely. A flat "<release>-v<N>.json" naming scheme alongside
+// canonical releases is ambiguous with any independently-named release that
+// happens to match it (e.g. a real release named "myapp-v2" next to a
+// canonical "myapp"); a dedicated subdirectory can't collide with a release
+// name, since Helm release names can't contain a path separator.
+const revisionsSubdir = "revisions"
+
+// revisionsDir returns the backend-relative directory revision copies live
+// under
+func (s *State) revisionsDir() string {
+	return s.Config.Backend.StoragePath + s.Backend.PathSeparator() + revisionsSubdir
+}
+
+// revisionFilePath returns the full backend path of the per-revision copy
+// of the given release name and version, e.g. ".../revisions/myrelease-v3.json"
+func (s *State) revisionFilePath(name string, version int32) string {
+	filename := fmt.Sprintf("%s-v%d%s", name, version, constants.ReleaseExtension)
+	return s.revisionsDir() + s.Backend.PathSeparator() + filename
+}
+
 // remoteFilePath returns the full appropriate backend file path based on the app's configuration
 func (s *State) remoteFilePath(name string) string {
 	if s.Config.Backend.StoragePath == s.Backend.PathSeparator() {
@@ -239,3 +397,12 @@ func (s *State) remoteFilePath(name string) string {
 	}
 	return s.Config.Backend.StoragePath + s.Backend.PathSeparator() + name
 }
+
+// logf calls the configured Logger, falling back to a no-op if none was set
+func (s *State) logf(level rlog.Level, format string, args ...interface{}) {
+	if s.Log == nil {
+		rlog.Nop(level, format, args...)
+		return
+	}
+	s.Log(level, format, args...)
+}
@@ -3,26 +3,52 @@ package transfer
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/logicmonitor/k8s-release-manager/pkg/client"
 	"github.com/logicmonitor/k8s-release-manager/pkg/config"
 	"github.com/logicmonitor/k8s-release-manager/pkg/constants"
 	"github.com/logicmonitor/k8s-release-manager/pkg/lmhelm"
+	rlog "github.com/logicmonitor/k8s-release-manager/pkg/log"
 	"github.com/logicmonitor/k8s-release-manager/pkg/release"
+	"github.com/logicmonitor/k8s-release-manager/pkg/releaseinstaller"
 	"github.com/logicmonitor/k8s-release-manager/pkg/state"
-	log "github.com/sirupsen/logrus"
+	"github.com/pmezard/go-difflib/difflib"
 	rls "k8s.io/helm/pkg/proto/hapi/release"
 )
 
+// lockRetryInterval is how long Run waits between AcquireLock attempts
+// while it's still within the configured lock-wait window
+const lockRetryInterval = time.Second
+
+// OnExistsSkip, OnExistsUpgrade, and OnExistsReplace are the supported
+// --on-exists values controlling what deployRelease does when a stored
+// release is already deployed to the cluster. OnExistsSkip is the default,
+// preserving the historical "fail/skip, don't clobber" behavior.
+const (
+	OnExistsSkip    = "skip"
+	OnExistsUpgrade = "upgrade"
+	OnExistsReplace = "replace"
+)
+
 // Transfer deploys remotely stored releases
 type Transfer struct {
 	Config     *config.Config
-	HelmClient *lmhelm.Client
+	HelmClient lmhelm.Interface
+	Installer  releaseinstaller.Installer
 	State      *state.State
+	// Log is called for informational and error messages produced while
+	// transferring. It defaults to a no-op if left unset.
+	Log rlog.Logger
+	// printMu serializes printDiff's stdout writes across the goroutines
+	// deployReleases fans out, so concurrent --diff-only diffs don't
+	// interleave.
+	printMu sync.Mutex
 }
 
-// New instantiates and returns a Deleter and an error if any.
-func New(rlsmgrconfig *config.Config, state *state.State) (*Transfer, error) {
+// New instantiates and returns a Transfer and an error if any. A nil logger
+// defaults to a no-op.
+func New(rlsmgrconfig *config.Config, state *state.State, logger rlog.Logger) (*Transfer, error) {
 	helmClient := &lmhelm.Client{}
 
 	kubernetesClient, kubernetesConfig, err := client.KubernetesClient(rlsmgrconfig.ClusterConfig)
@@ -34,18 +60,48 @@ func New(rlsmgrconfig *config.Config, state *state.State) (*Transfer, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	installer, err := releaseinstaller.New(rlsmgrconfig.Transfer.Installer, rlsmgrconfig.Transfer.RudderAddress, helmClient)
+	if err != nil {
+		return nil, err
+	}
+
+	// ExecInstaller.Status can only confirm a release exists, not what's
+	// actually deployed (helm status doesn't expose chart/values), so it
+	// can't produce a meaningful --diff-only diff. Fail fast here rather
+	// than silently diffing against an empty placeholder release.
+	if rlsmgrconfig.Transfer.DiffOnly && rlsmgrconfig.Transfer.Installer == releaseinstaller.Exec {
+		return nil, fmt.Errorf("--diff-only is not supported with --installer=%s: it can't report the deployed chart/values to diff against", releaseinstaller.Exec)
+	}
+
+	if logger == nil {
+		logger = rlog.Nop
+	}
 	return &Transfer{
 		Config:     rlsmgrconfig,
 		HelmClient: helmClient,
+		Installer:  installer,
 		State:      state,
+		Log:        logger,
 	}, nil
 }
 
 // Run the Transfer.
 func (t *Transfer) Run() error {
+	unlock, err := t.acquireLock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	releases, err := t.State.Releases.StoredReleases()
 	if err != nil {
-		log.Fatalf("Error retrieving stored releases: %v", err)
+		return fmt.Errorf("Error retrieving stored releases: %v", err)
+	}
+
+	releases, err = t.processReleases(releases)
+	if err != nil {
+		return err
 	}
 
 	err = t.State.Read()
@@ -60,6 +116,124 @@ func (t *Transfer) Run() error {
 	return t.deployReleases(releases)
 }
 
+// acquireLock obtains the backend lock scoped to the configured storage
+// path, retrying until --lock-wait elapses, so that a concurrent manager
+// writing to the same path can't race with this transfer. --lock-ttl and
+// --lock-wait live on Config.Backend, the same place State reads them from,
+// so a single pair of flags governs the lock regardless of which package
+// acquires it.
+func (t *Transfer) acquireLock() (func(), error) {
+	path := t.Config.Backend.StoragePath
+	deadline := time.Now().Add(t.Config.Backend.LockWait)
+
+	var token string
+	var err error
+	for {
+		token, err = t.State.Backend.AcquireLock(path, t.Config.Backend.LockTTL)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("Unable to acquire backend lock for %s after %s: %v", path, t.Config.Backend.LockWait, err)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	stop := t.renewLock(path, token)
+	return func() {
+		stop()
+		if e := t.State.Backend.ReleaseLock(path, token); e != nil {
+			t.logf(rlog.Warn, "Error releasing backend lock for %s: %v", path, e)
+		}
+	}, nil
+}
+
+// renewLock extends the held lock at half its TTL for as long as the
+// returned stop func hasn't been called, so a transfer that outlives a
+// single LockTTL keeps holding it instead of letting it expire out from
+// under the operation. It logs and gives up
+// renewing, rather than failing the in-flight operation, if a renewal
+// attempt errors.
+func (t *Transfer) renewLock(path, token string) func() {
+	ttl := t.Config.Backend.LockTTL
+	if ttl <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(ttl / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := t.State.Backend.RenewLock(path, token, ttl); err != nil {
+					t.logf(rlog.Warn, "Error renewing backend lock for %s: %v. A concurrent manager may take over the lock.", path, err)
+					return
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// logf calls the configured Logger, falling back to a no-op if none was set
+func (t *Transfer) logf(level rlog.Level, format string, args ...interface{}) {
+	if t.Log == nil {
+		rlog.Nop(level, format, args...)
+		return
+	}
+	t.Log(level, format, args...)
+}
+
+// processReleases applies the configured namespace filter to releases
+// before they're deployed.
+func (t *Transfer) processReleases(releases []*rls.Release) ([]*rls.Release, error) {
+	return t.filterReleasesByNamespace(releases), nil
+}
+
+// filterReleasesByNamespace honors --namespace (include only that
+// namespace) and --exclude-namespaces (deploy everything except those
+// namespaces); --namespace takes precedence if both are set.
+func (t *Transfer) filterReleasesByNamespace(releases []*rls.Release) []*rls.Release {
+	if t.Config.Transfer.Namespace != "" {
+		return t.includeReleasesByNamespace(releases)
+	}
+	if len(t.Config.Transfer.ExcludeNamespaces) > 0 {
+		return t.excludeReleasesByNamespace(releases)
+	}
+	return releases
+}
+
+func (t *Transfer) includeReleasesByNamespace(releases []*rls.Release) []*rls.Release {
+	var deploy []*rls.Release
+	for _, r := range releases {
+		if r.GetNamespace() == t.Config.Transfer.Namespace {
+			deploy = append(deploy, r)
+		}
+	}
+	return deploy
+}
+
+func (t *Transfer) excludeReleasesByNamespace(releases []*rls.Release) []*rls.Release {
+	var deploy []*rls.Release
+	for _, r := range releases {
+		excluded := false
+		for _, namespace := range t.Config.Transfer.ExcludeNamespaces {
+			if r.GetNamespace() == namespace {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			deploy = append(deploy, r)
+		}
+	}
+	return deploy
+}
+
 func (t *Transfer) deployReleases(releases []*rls.Release) error {
 	var err error
 	var wg sync.WaitGroup
@@ -68,7 +242,7 @@ func (t *Transfer) deployReleases(releases []*rls.Release) error {
 
 		r, err = t.updateManagerRelease(r)
 		if err != nil {
-			log.Errorf("Unable to update the output path for the new release manager chart. Skipping.")
+			t.logf(rlog.Error, "Unable to update the output path for the new release manager chart. Skipping.")
 			continue
 		}
 
@@ -88,10 +262,85 @@ func (t *Transfer) deployReleases(releases []*rls.Release) error {
 }
 
 func (t *Transfer) deployRelease(r *rls.Release) {
-	err := t.HelmClient.Install(r)
+	existing, err := t.Installer.Status(r.GetName())
+	if err != nil && !lmhelm.ErrorReleaseNotFound(err) {
+		t.logf(rlog.Error, "Error checking status of release %s: %v", r.GetName(), err)
+		return
+	}
+
+	if t.Config.Transfer.DiffOnly {
+		if existing == nil {
+			fmt.Printf("Release %s is not yet deployed\n", r.GetName())
+			return
+		}
+		t.printDiff(existing, r)
+		return
+	}
+
+	if existing == nil {
+		t.installRelease(r)
+		return
+	}
+
+	switch t.Config.Transfer.OnExists {
+	case OnExistsUpgrade:
+		t.upgradeRelease(r)
+	case OnExistsReplace:
+		t.replaceRelease(r)
+	default:
+		t.logf(rlog.Warn, "Skipping release: %s already exists", r.GetName())
+	}
+}
+
+func (t *Transfer) installRelease(r *rls.Release) {
+	err := t.Installer.Install(r)
+	if err != nil {
+		t.logf(rlog.Error, "Error deploying release %s: %v", r.GetName(), err)
+	}
+}
+
+func (t *Transfer) upgradeRelease(r *rls.Release) {
+	err := t.Installer.Upgrade(r)
+	if err != nil {
+		t.logf(rlog.Error, "Error upgrading release %s: %v", r.GetName(), err)
+	}
+}
+
+func (t *Transfer) replaceRelease(r *rls.Release) {
+	err := t.Installer.Uninstall(r)
+	if err != nil {
+		t.logf(rlog.Error, "Error removing existing release %s before replace: %v", r.GetName(), err)
+		return
+	}
+	t.installRelease(r)
+}
+
+// printDiff renders the release currently deployed to the cluster and the
+// stored release as a unified diff, without mutating anything. It's used
+// by --diff-only so repeated transfers can be previewed before --on-exists
+// actually changes the cluster.
+func (t *Transfer) printDiff(existing, r *rls.Release) {
+	t.printMu.Lock()
+	defer t.printMu.Unlock()
+
+	diff := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(release.ToString(existing, t.Config.VerboseMode)),
+		B:        difflib.SplitLines(release.ToString(r, t.Config.VerboseMode)),
+		FromFile: fmt.Sprintf("%s (cluster)", r.GetName()),
+		ToFile:   fmt.Sprintf("%s (stored)", r.GetName()),
+		Context:  3,
+	}
+
+	text, err := difflib.GetUnifiedDiffString(diff)
 	if err != nil {
-		log.Errorf("Error deploying release %s: %v", r.GetName(), err)
+		t.logf(rlog.Error, "Error diffing release %s: %v", r.GetName(), err)
+		return
+	}
+	if text == "" {
+		fmt.Printf("No differences for release %s\n", r.GetName())
+		return
 	}
+	fmt.Print(text)
 }
 
 // if this is the release manager release, update the backend path, else return unmodified
@@ -118,7 +367,7 @@ func (t *Transfer) sanityCheck() error {
 	case t.State != nil && t.Config.Transfer.NewStoragePath == "":
 		return t.resolveStateConflict()
 	case t.State == nil && t.Config.Transfer.NewStoragePath != "":
-		log.Warnf("--path specified but no remote state found.")
+		t.logf(rlog.Warn, "--path specified but no remote state found.")
 		return nil
 	case t.State == nil && t.Config.Transfer.NewStoragePath == "":
 		return nil
@@ -138,7 +387,7 @@ func (t *Transfer) resolveStateConflict() error {
 
 	// in case the user REALLY wants to proceed anyway
 	if t.Config.Transfer.Force {
-		log.Warnf("%s\n--force specified. Proceeding...", msg)
+		t.logf(rlog.Warn, "%s\n--force specified. Proceeding...", msg)
 		return nil
 	}
 
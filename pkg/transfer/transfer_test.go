@@ -0,0 +1,201 @@
+package transfer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/logicmonitor/k8s-release-manager/internal/mocks"
+	"github.com/logicmonitor/k8s-release-manager/pkg/backend"
+	"github.com/logicmonitor/k8s-release-manager/pkg/config"
+	rlog "github.com/logicmonitor/k8s-release-manager/pkg/log"
+	"github.com/logicmonitor/k8s-release-manager/pkg/releaseinstaller"
+	"github.com/logicmonitor/k8s-release-manager/pkg/state"
+	rls "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func newRelease(name, namespace string) *rls.Release {
+	return &rls.Release{Name: name, Namespace: namespace}
+}
+
+func TestFilterReleasesByNamespace(t *testing.T) {
+	releases := []*rls.Release{
+		newRelease("a", "ns1"),
+		newRelease("b", "ns2"),
+		newRelease("c", "ns3"),
+	}
+
+	cases := []struct {
+		name              string
+		namespace         string
+		excludeNamespaces []string
+		want              []string
+	}{
+		{"no filter", "", nil, []string{"a", "b", "c"}},
+		{"include namespace", "ns2", nil, []string{"b"}},
+		{"exclude namespaces", "", []string{"ns1", "ns3"}, []string{"b"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tr := &Transfer{
+				Config: &config.Config{
+					Transfer: &config.TransferConfig{
+						Namespace:         c.namespace,
+						ExcludeNamespaces: c.excludeNamespaces,
+					},
+				},
+			}
+
+			got := tr.filterReleasesByNamespace(releases)
+			if len(got) != len(c.want) {
+				t.Fatalf("got %d releases, want %d", len(got), len(c.want))
+			}
+			for i, r := range got {
+				if r.GetName() != c.want[i] {
+					t.Errorf("got release %s at index %d, want %s", r.GetName(), i, c.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestProcessReleases(t *testing.T) {
+	tr := &Transfer{
+		Config: &config.Config{
+			Transfer: &config.TransferConfig{
+				Namespace: "ns1",
+			},
+		},
+		Log: func(rlog.Level, string, ...interface{}) {},
+	}
+
+	releases := []*rls.Release{newRelease("a", "ns1"), newRelease("b", "ns2")}
+	got, err := tr.processReleases(releases)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].GetName() != "a" {
+		t.Fatalf("expected only release 'a' to survive namespace filtering, got %v", got)
+	}
+}
+
+func TestUpdateManagerRelease(t *testing.T) {
+	cases := []struct {
+		name           string
+		newStoragePath string
+		info           *state.Info
+		release        *rls.Release
+	}{
+		{"no new path", "", &state.Info{ReleaseName: "mgr"}, newRelease("mgr", "ns1")},
+		{"no stored info", "/new", nil, newRelease("mgr", "ns1")},
+		{"not the manager release", "/new", &state.Info{ReleaseName: "mgr"}, newRelease("other", "ns1")},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tr := &Transfer{
+				Config: &config.Config{
+					Transfer: &config.TransferConfig{NewStoragePath: c.newStoragePath},
+				},
+				State: &state.State{Info: c.info},
+			}
+
+			got, err := tr.updateManagerRelease(c.release)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.release {
+				t.Errorf("expected the release to be returned unmodified")
+			}
+		})
+	}
+}
+
+// newManagerState returns a State tracking a manager release, with the
+// Config and Backend that resolveStateConflict needs to build State.Path().
+func newManagerState() *state.State {
+	return &state.State{
+		Backend: &backend.FilesystemBackend{},
+		Config:  &config.Config{Backend: config.BackendConfig{StoragePath: "/state"}},
+		Info:    &state.Info{ReleaseName: "mgr"},
+	}
+}
+
+func TestSanityCheck(t *testing.T) {
+	cases := []struct {
+		name           string
+		state          *state.State
+		newStoragePath string
+		force          bool
+		dryRun         bool
+		wantErr        bool
+	}{
+		{"no state, no new path", nil, "", false, false, false},
+		{"no state, new path set", nil, "/new", false, false, false},
+		{"state conflict, no new path, no force", newManagerState(), "", false, false, true},
+		{"state conflict, force", newManagerState(), "", true, false, false},
+		{"state conflict, dry run", newManagerState(), "", false, true, false},
+		{"state and new path set", newManagerState(), "/new", false, false, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tr := &Transfer{
+				Config: &config.Config{
+					DryRun: c.dryRun,
+					Transfer: &config.TransferConfig{
+						NewStoragePath: c.newStoragePath,
+						Force:          c.force,
+					},
+				},
+				State: c.state,
+				Log:   func(rlog.Level, string, ...interface{}) {},
+			}
+
+			err := tr.sanityCheck()
+			if (err != nil) != c.wantErr {
+				t.Fatalf("got error %v, wantErr %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeployRelease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("successful install", func(t *testing.T) {
+		var logged []string
+		helmClient := mocks.NewMockInterface(ctrl)
+		helmClient.EXPECT().ReleaseStatus(gomock.Any()).Return(nil, nil)
+		helmClient.EXPECT().Install(gomock.Any()).Return(nil)
+
+		tr := &Transfer{
+			Config:    &config.Config{Transfer: &config.TransferConfig{}},
+			Installer: &releaseinstaller.HelmInstaller{Client: helmClient},
+			Log:       func(level rlog.Level, format string, args ...interface{}) { logged = append(logged, format) },
+		}
+		tr.deployRelease(newRelease("a", "ns1"))
+		if len(logged) != 0 {
+			t.Fatalf("expected no errors logged, got %v", logged)
+		}
+	})
+
+	t.Run("install error", func(t *testing.T) {
+		var logged []string
+		helmClient := mocks.NewMockInterface(ctrl)
+		helmClient.EXPECT().ReleaseStatus(gomock.Any()).Return(nil, nil)
+		helmClient.EXPECT().Install(gomock.Any()).Return(errors.New("boom"))
+
+		tr := &Transfer{
+			Config:    &config.Config{Transfer: &config.TransferConfig{}},
+			Installer: &releaseinstaller.HelmInstaller{Client: helmClient},
+			Log:       func(level rlog.Level, format string, args ...interface{}) { logged = append(logged, format) },
+		}
+		tr.deployRelease(newRelease("a", "ns1"))
+		if len(logged) != 1 {
+			t.Fatalf("expected an error to be logged, got %v", logged)
+		}
+	})
+}
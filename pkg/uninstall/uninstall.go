@@ -0,0 +1,62 @@
+package uninstall
+
+import (
+	"fmt"
+
+	"github.com/logicmonitor/k8s-release-manager/pkg/client"
+	"github.com/logicmonitor/k8s-release-manager/pkg/config"
+	"github.com/logicmonitor/k8s-release-manager/pkg/lmhelm"
+	rlog "github.com/logicmonitor/k8s-release-manager/pkg/log"
+	"github.com/logicmonitor/k8s-release-manager/pkg/state"
+)
+
+// Uninstall removes a release from the cluster
+type Uninstall struct {
+	Config     *config.Config
+	HelmClient lmhelm.Interface
+	State      *state.State
+	// Log is called for informational and error messages produced while
+	// uninstalling. It defaults to a no-op if left unset.
+	Log rlog.Logger
+}
+
+// New instantiates and returns an Uninstall and an error if any. A nil
+// logger defaults to a no-op.
+func New(rlsmgrconfig *config.Config, state *state.State, logger rlog.Logger) (*Uninstall, error) {
+	helmClient := &lmhelm.Client{}
+
+	kubernetesClient, kubernetesConfig, err := client.KubernetesClient(rlsmgrconfig.ClusterConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	err = helmClient.Init(rlsmgrconfig.Helm, kubernetesClient, kubernetesConfig)
+	if err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = rlog.Nop
+	}
+	return &Uninstall{
+		Config:     rlsmgrconfig,
+		HelmClient: helmClient,
+		State:      state,
+		Log:        logger,
+	}, nil
+}
+
+// Run the Uninstall
+func (u *Uninstall) Run() error {
+	name := u.Config.Uninstall.ReleaseName
+	fmt.Printf("Uninstalling release %s\n", name)
+
+	if u.Config.DryRun {
+		return nil
+	}
+
+	if err := u.HelmClient.Uninstall(name); err != nil {
+		return fmt.Errorf("Error uninstalling release %s: %v", name, err)
+	}
+	fmt.Printf("Successfully uninstalled release %s\n", name)
+	return nil
+}